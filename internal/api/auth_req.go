@@ -0,0 +1,10 @@
+package api
+
+// AuthReq 统一登录请求，grant_type 决定 identifier/credential 的含义
+type AuthReq struct {
+	GrantType     string `json:"grant_type" binding:"required"` // password | sms_code | email_code；captcha 不被接受，按不支持的登录方式返回
+	Identifier    string `json:"identifier" binding:"required"` // 邮箱或手机号
+	Credential    string `json:"credential" binding:"required"` // 密码或验证码
+	CaptchaID     string `json:"captcha_id"`                    // 密码登录触发人机验证时必填
+	CaptchaAnswer string `json:"captcha_answer"`                // 密码登录触发人机验证时必填
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"strconv"
+
+	. "github.com/GoSimplicity/LinkMe/internal/constants"
+	"github.com/GoSimplicity/LinkMe/internal/service"
+	. "github.com/GoSimplicity/LinkMe/pkg/ginp"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RankingHandler 热榜查询接口
+type RankingHandler struct {
+	svc service.RankingService
+	l   *zap.Logger
+}
+
+func NewRankingHandler(svc service.RankingService, l *zap.Logger) *RankingHandler {
+	return &RankingHandler{svc: svc, l: l}
+}
+
+func (rh *RankingHandler) RegisterRoutes(server *gin.Engine) {
+	rankingGroup := server.Group("/api/ranking")
+	rankingGroup.GET("/:biz", rh.GetTopN)
+}
+
+// GetTopN 查询指定biz当前的热榜，n 通过 query 参数传入，默认使用服务端配置的TopN
+func (rh *RankingHandler) GetTopN(ctx *gin.Context) {
+	biz := ctx.Param("biz")
+	n, _ := strconv.Atoi(ctx.Query("n"))
+	items, err := rh.svc.GetTopN(ctx.Request.Context(), biz, n)
+	if err != nil {
+		rh.l.Error("get ranking top n failed", zap.String("biz", biz), zap.Error(err))
+		ctx.JSON(200, Result{
+			Code: RankingGetTopNError,
+			Msg:  RankingGetTopNErrMsg,
+		})
+		return
+	}
+	ctx.JSON(200, Result{
+		Code: RequestsOK,
+		Msg:  RankingGetTopNSuccess,
+		Data: items,
+	})
+}
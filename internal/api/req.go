@@ -0,0 +1,82 @@
+package api
+
+// SignUpReq 注册请求
+type SignUpReq struct {
+	Email           string `json:"email" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+	ConfirmPassword string `json:"confirmPassword" binding:"required"`
+	CaptchaID       string `json:"captchaId"`     // 触发限流后必填
+	CaptchaAnswer   string `json:"captchaAnswer"` // 触发限流后必填
+}
+
+// LoginReq 邮箱密码登录请求
+type LoginReq struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginSMSReq 短信验证码登录请求
+type LoginSMSReq struct {
+	Number string `json:"number" binding:"required"`
+	Code   string `json:"code" binding:"required"`
+}
+
+// SMSReq 发送短信验证码请求
+type SMSReq struct {
+	Number        string `json:"number" binding:"required"`
+	CaptchaID     string `json:"captchaId"`     // 触发限流后必填
+	CaptchaAnswer string `json:"captchaAnswer"` // 触发限流后必填
+}
+
+// EmailReq 发送邮箱验证码请求
+type EmailReq struct {
+	Email         string `json:"email" binding:"required"`
+	CaptchaID     string `json:"captchaId"`     // 触发限流后必填
+	CaptchaAnswer string `json:"captchaAnswer"` // 触发限流后必填
+}
+
+// ChangeReq 修改密码请求
+type ChangeReq struct {
+	Email           string `json:"email" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+	ConfirmPassword string `json:"confirmPassword" binding:"required"`
+	CaptchaID       string `json:"captchaId"`     // 触发限流后必填
+	CaptchaAnswer   string `json:"captchaAnswer"` // 触发限流后必填
+}
+
+// DeleteUserReq 注销账号请求
+type DeleteUserReq struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UpdateProfileReq 更新用户资料请求
+type UpdateProfileReq struct {
+	NickName string `json:"nickName"`
+	Avatar   string `json:"avatar"`
+	About    string `json:"about"`
+	Birthday string `json:"birthday"`
+}
+
+// ListUserReq 管理员分页查询用户列表请求
+type ListUserReq struct {
+	Page int `json:"page" binding:"required"`
+	Size int `json:"size" binding:"required"`
+}
+
+// GetUserCountReq 管理员查询用户总数请求
+type GetUserCountReq struct {
+}
+
+// GetCaptchaReq 获取验证码请求
+type GetCaptchaReq struct {
+	Kind string `form:"kind" binding:"required"` // image | slider
+}
+
+// StatusReq 管理员变更用户账号状态请求
+type StatusReq struct {
+	UID          int64  `json:"uid" binding:"required"`
+	TargetStatus string `json:"targetStatus" binding:"required"` // active | frozen | banned | written_off
+	Reason       string `json:"reason"`
+}
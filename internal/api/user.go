@@ -2,12 +2,16 @@ package api
 
 import (
 	"errors"
+	"time"
+
 	. "github.com/GoSimplicity/LinkMe/internal/constants"
 	"github.com/GoSimplicity/LinkMe/internal/domain"
 	"github.com/GoSimplicity/LinkMe/internal/domain/events/email"
 	"github.com/GoSimplicity/LinkMe/internal/domain/events/sms"
+	"github.com/GoSimplicity/LinkMe/internal/repository/cache"
 	"github.com/GoSimplicity/LinkMe/internal/service"
 	"github.com/GoSimplicity/LinkMe/middleware"
+	"github.com/GoSimplicity/LinkMe/pkg/captcha"
 	. "github.com/GoSimplicity/LinkMe/pkg/ginp"
 	"github.com/GoSimplicity/LinkMe/utils"
 	ijwt "github.com/GoSimplicity/LinkMe/utils/jwt"
@@ -23,6 +27,13 @@ const (
 	passwordRegexPattern = `^(?=.*[A-Za-z])(?=.*\d)(?=.*[$@$!%*#?&])[A-Za-z\d$@$!%*#?&]{8,}$`
 )
 
+// captchaRateLimitWindow/captchaRateLimitThreshold 控制何时对高风险接口强制要求验证码：
+// 同一维度（IP/邮箱/手机号）在窗口期内的请求数达到阈值后，后续请求必须携带有效验证码
+const (
+	captchaRateLimitWindow    = time.Minute
+	captchaRateLimitThreshold = 5
+)
+
 type UserHandler struct {
 	Email         *regexp.Regexp
 	PassWord      *regexp.Regexp
@@ -32,9 +43,11 @@ type UserHandler struct {
 	ce            *casbin.Enforcer
 	smsProducer   sms.Producer
 	emailProducer email.Producer
+	captcha       captcha.Captcha
+	rateLimiter   cache.RateLimiter
 }
 
-func NewUserHandler(svc service.UserService, j ijwt.Handler, l *zap.Logger, smsProducer sms.Producer, emailProducer email.Producer, ce *casbin.Enforcer) *UserHandler {
+func NewUserHandler(svc service.UserService, j ijwt.Handler, l *zap.Logger, smsProducer sms.Producer, emailProducer email.Producer, ce *casbin.Enforcer, cp captcha.Captcha, rateLimiter cache.RateLimiter) *UserHandler {
 	return &UserHandler{
 		Email:         regexp.MustCompile(emailRegexPattern, regexp.None),
 		PassWord:      regexp.MustCompile(passwordRegexPattern, regexp.None),
@@ -44,29 +57,72 @@ func NewUserHandler(svc service.UserService, j ijwt.Handler, l *zap.Logger, smsP
 		ce:            ce,
 		smsProducer:   smsProducer,
 		emailProducer: emailProducer,
+		captcha:       cp,
+		rateLimiter:   rateLimiter,
+	}
+}
+
+// checkCaptchaIfRequired 仅当 key 维度（IP/邮箱/手机号）的请求频率触发了滑动窗口限流时才要求验证码，
+// 未触发限流时直接放行，避免给正常用户增加摩擦
+func (uh *UserHandler) checkCaptchaIfRequired(ctx *gin.Context, key, captchaID, captchaAnswer string) (bool, error) {
+	allowed, err := uh.rateLimiter.Allow(ctx.Request.Context(), key, captchaRateLimitWindow, captchaRateLimitThreshold)
+	if err != nil {
+		return false, err
 	}
+	if allowed {
+		return true, nil
+	}
+	if captchaID == "" || captchaAnswer == "" {
+		return false, nil
+	}
+	return uh.captcha.Verify(ctx.Request.Context(), captchaID, captchaAnswer)
 }
 
 func (uh *UserHandler) RegisterRoutes(server *gin.Engine) {
 	casbinMiddleware := middleware.NewCasbinMiddleware(uh.ce, uh.l)
+	// userStatusMiddleware 拦截非active账号访问需要正常账号状态的已登录接口；RefreshToken 不走这里，
+	// 因为它要在签发新token前自行判断并返回差异化提示（见 checkUserStatus）
+	userStatusMiddleware := middleware.NewUserStatusMiddleware(uh.svc)
 	userGroup := server.Group("/api/users")
 	userGroup.POST("/signup", WrapBody(uh.SignUp))
-	userGroup.POST("/login", WrapBody(uh.Login))
-	userGroup.POST("/login_sms", WrapBody(uh.LoginSMS))
+	userGroup.POST("/auth", WrapBody(uh.Auth))
+	userGroup.POST("/login", WrapBody(uh.Login))         // 兼容旧版，内部转发到 Auth
+	userGroup.POST("/login_sms", WrapBody(uh.LoginSMS))  // 兼容旧版，内部转发到 Auth
 	userGroup.POST("/send_sms", WrapBody(uh.SendSMS))
 	userGroup.POST("/send_email", WrapBody(uh.SendEmail))
 	userGroup.POST("/logout", uh.Logout)
 	userGroup.POST("/refresh_token", uh.RefreshToken)
-	userGroup.POST("/change_password", WrapBody(uh.ChangePassword))
+	userGroup.POST("/change_password", userStatusMiddleware.CheckActive(), WrapBody(uh.ChangePassword))
 	userGroup.DELETE("/write_off", WrapBody(uh.WriteOff))
-	userGroup.GET("/profile", uh.GetProfile)
-	userGroup.POST("/update_profile", WrapBody(uh.UpdateProfileByID))
+	userGroup.GET("/profile", userStatusMiddleware.CheckActive(), uh.GetProfile)
+	userGroup.POST("/update_profile", userStatusMiddleware.CheckActive(), WrapBody(uh.UpdateProfileByID))
 	userGroup.POST("/list", casbinMiddleware.CheckCasbin(), WrapBody(uh.ListUser))      // 管理员使用
 	userGroup.GET("/stats", casbinMiddleware.CheckCasbin(), WrapQuery(uh.GetUserCount)) // 管理员使用
+	userGroup.POST("/status", casbinMiddleware.CheckCasbin(), WrapBody(uh.UpdateStatus)) // 管理员使用
 	// 测试接口
 	userGroup.GET("/hello", func(ctx *gin.Context) {
 		ctx.JSON(200, "hello world!")
 	})
+	server.GET("/api/captcha", WrapQuery(uh.GetCaptcha))
+}
+
+// GetCaptcha 获取验证码，kind 取值 image 或 slider
+func (uh *UserHandler) GetCaptcha(ctx *gin.Context, req GetCaptchaReq) (Result, error) {
+	kind := captcha.Kind(req.Kind)
+	id, payload, err := uh.captcha.Generate(ctx.Request.Context(), kind)
+	if err != nil {
+		uh.l.Error("generate captcha failed", zap.String("kind", req.Kind), zap.Error(err))
+		return Result{
+			Code: UserServerErrorCode,
+		}, err
+	}
+	return Result{
+		Code: RequestsOK,
+		Data: gin.H{
+			"id":      id,
+			"payload": payload,
+		},
+	}, nil
 }
 
 // SignUp 用户注册
@@ -106,6 +162,20 @@ func (uh *UserHandler) SignUp(ctx *gin.Context, req SignUpReq) (Result, error) {
 			Msg:  UserPasswordFormatError,
 		}, nil
 	}
+	// 注册属于高风险操作，触发限流后必须携带验证码，避免批量注册攻击
+	captchaOK, err := uh.checkCaptchaIfRequired(ctx, "signup:"+ctx.ClientIP(), req.CaptchaID, req.CaptchaAnswer)
+	if err != nil {
+		return Result{
+			Code: UserServerErrorCode,
+			Msg:  UserSignUpFailure,
+		}, err
+	}
+	if !captchaOK {
+		return Result{
+			Code: UserCaptchaInvalidCode,
+			Msg:  UserCaptchaInvalid,
+		}, nil
+	}
 	// 尝试注册用户
 	err = uh.svc.SignUp(ctx.Request.Context(), domain.User{
 		Email:    req.Email,
@@ -131,24 +201,13 @@ func (uh *UserHandler) SignUp(ctx *gin.Context, req SignUpReq) (Result, error) {
 	}, nil
 }
 
-// Login 登陆
+// Login 登陆，为兼容旧版前端保留，内部转发到统一的 Auth 入口
 func (uh *UserHandler) Login(ctx *gin.Context, req LoginReq) (Result, error) {
-	du, err := uh.svc.Login(ctx, req.Email, req.Password)
-	if err == nil {
-		err = uh.ijwt.SetLoginToken(ctx, du.ID)
-		return Result{
-			Code: RequestsOK,
-			Msg:  UserLoginSuccess,
-		}, nil
-	} else if errors.Is(err, service.ErrInvalidUserOrPassword) {
-		return Result{
-			Code: UserInvalidOrPasswordCode,
-			Msg:  UserLoginFailure,
-		}, nil
-	}
-	return Result{
-		Code: UserServerErrorCode,
-	}, err
+	return uh.Auth(ctx, AuthReq{
+		GrantType:  string(service.GrantTypePassword),
+		Identifier: req.Email,
+		Credential: req.Password,
+	})
 }
 
 // Logout 登出
@@ -185,6 +244,11 @@ func (uh *UserHandler) RefreshToken(ctx *gin.Context) {
 		ctx.AbortWithStatus(ServerERROR)
 		return
 	}
+	// 非active账号（冻结/封禁/注销/待验证）一律拒绝刷新，并返回区分度更高的响应码供前端展示
+	if code, msg, blocked := uh.checkUserStatus(ctx, rc.Uid); blocked {
+		ctx.JSON(RequestsOK, gin.H{"code": code, "message": msg})
+		return
+	}
 	// 刷新短token
 	if err = uh.ijwt.SetJWTToken(ctx, rc.Uid, rc.Ssid); err != nil {
 		ctx.AbortWithStatus(ServerERROR)
@@ -195,6 +259,16 @@ func (uh *UserHandler) RefreshToken(ctx *gin.Context) {
 	})
 }
 
+// checkUserStatus 复用 middleware.StatusRejection 的状态->响应码映射，blocked=false 时表示账号可正常使用，
+// 避免与 UserStatusMiddleware 各自维护一份同样的switch
+func (uh *UserHandler) checkUserStatus(ctx *gin.Context, uid int64) (code int, msg string, blocked bool) {
+	status, err := uh.svc.GetStatus(ctx.Request.Context(), uid)
+	if err != nil {
+		return UserServerErrorCode, UserLoginFailure, true
+	}
+	return middleware.StatusRejection(status)
+}
+
 func (uh *UserHandler) SendSMS(ctx *gin.Context, req SMSReq) (Result, error) {
 	valid := utils.IsValidNumber(req.Number)
 	if !valid {
@@ -204,6 +278,17 @@ func (uh *UserHandler) SendSMS(ctx *gin.Context, req SMSReq) (Result, error) {
 			Msg:  InvalidNumber,
 		}, nil
 	}
+	// 发送短信会触发下游Kafka消费者，未授权的高频请求会造成队列滥用，限流触发后必须先过验证码
+	captchaOK, err := uh.checkCaptchaIfRequired(ctx, "send_sms:"+req.Number, req.CaptchaID, req.CaptchaAnswer)
+	if err != nil {
+		return Result{}, err
+	}
+	if !captchaOK {
+		return Result{
+			Code: UserCaptchaInvalidCode,
+			Msg:  UserCaptchaInvalid,
+		}, nil
+	}
 	if err := uh.smsProducer.ProduceSMSCode(ctx, sms.SMSCodeEvent{Number: req.Number}); err != nil {
 		uh.l.Error("kafka produce sms failed", zap.Error(err))
 		return Result{}, err
@@ -222,7 +307,21 @@ func (uh *UserHandler) ChangePassword(ctx *gin.Context, req ChangeReq) (Result,
 			Msg:  UserPasswordMismatchError,
 		}, nil
 	}
-	err := uh.svc.ChangePassword(ctx.Request.Context(), req.Email, req.Password, req.NewPassword, req.ConfirmPassword)
+	// 修改密码属于高风险操作，限流触发后必须携带验证码
+	captchaOK, err := uh.checkCaptchaIfRequired(ctx, "change_password:"+req.Email, req.CaptchaID, req.CaptchaAnswer)
+	if err != nil {
+		return Result{
+			Code: UserServerErrorCode,
+			Msg:  UserPasswordChangeFailure,
+		}, err
+	}
+	if !captchaOK {
+		return Result{
+			Code: UserCaptchaInvalidCode,
+			Msg:  UserCaptchaInvalid,
+		}, nil
+	}
+	err = uh.svc.ChangePassword(ctx.Request.Context(), req.Email, req.Password, req.NewPassword, req.ConfirmPassword)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidUserOrPassword) {
 			return Result{
@@ -252,6 +351,17 @@ func (uh *UserHandler) SendEmail(ctx *gin.Context, req EmailReq) (Result, error)
 			Msg:  UserEmailFormatError,
 		}, nil
 	}
+	// 发送邮件会触发下游Kafka消费者，限流触发后必须先过验证码，避免未授权端点被用来刷队列
+	captchaOK, err := uh.checkCaptchaIfRequired(ctx, "send_email:"+req.Email, req.CaptchaID, req.CaptchaAnswer)
+	if err != nil {
+		return Result{}, err
+	}
+	if !captchaOK {
+		return Result{
+			Code: UserCaptchaInvalidCode,
+			Msg:  UserCaptchaInvalid,
+		}, nil
+	}
 	if err = uh.emailProducer.ProduceEmail(ctx, email.EmailEvent{Email: req.Email}); err != nil {
 		return Result{}, err
 	}
@@ -321,8 +431,99 @@ func (uh *UserHandler) UpdateProfileByID(ctx *gin.Context, req UpdateProfileReq)
 	}, nil
 }
 
+// LoginSMS 短信验证码登录，为兼容旧版前端保留，内部转发到统一的 Auth 入口
 func (uh *UserHandler) LoginSMS(ctx *gin.Context, req LoginSMSReq) (Result, error) {
-	return Result{}, nil
+	return uh.Auth(ctx, AuthReq{
+		GrantType:  string(service.GrantTypeSMSCode),
+		Identifier: req.Number,
+		Credential: req.Code,
+	})
+}
+
+// Auth 统一多授权方式登录入口，grant_type 决定 identifier/credential 的含义：
+// password: identifier=email, credential=password（支持 captcha_id/captcha_answer）
+// sms_code: identifier=phone, credential=code
+// email_code: identifier=email, credential=code
+func (uh *UserHandler) Auth(ctx *gin.Context, req AuthReq) (Result, error) {
+	grantType := service.GrantType(req.GrantType)
+	var (
+		du  domain.User
+		err error
+	)
+	switch grantType {
+	case service.GrantTypePassword:
+		du, err = uh.svc.SignInPassword(ctx, req.Identifier, req.Credential, req.CaptchaID, req.CaptchaAnswer, loginFailKey(ctx, req.Identifier))
+	case service.GrantTypeSMSCode:
+		du, err = uh.svc.SignInSMS(ctx, req.Identifier, req.Credential)
+	case service.GrantTypeEmailCode:
+		du, err = uh.svc.SignInEmail(ctx, req.Identifier, req.Credential)
+	default:
+		return Result{
+			Code: UserUnsupportedGrantTypeCode,
+			Msg:  UserUnsupportedGrantType,
+		}, nil
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidUserOrPassword):
+			return Result{
+				Code: UserInvalidOrPasswordCode,
+				Msg:  UserLoginFailure,
+			}, nil
+		case errors.Is(err, service.ErrCodeInvalid):
+			return Result{
+				Code: UserCodeInvalidCode,
+				Msg:  UserCodeInvalid,
+			}, nil
+		case errors.Is(err, cache.ErrCodeVerifyTooManyTimes):
+			return Result{
+				Code: UserCodeVerifyTooManyCode,
+				Msg:  UserCodeVerifyTooMany,
+			}, nil
+		case errors.Is(err, service.ErrCaptchaRequired), errors.Is(err, service.ErrCaptchaInvalid):
+			return Result{
+				Code: UserCaptchaInvalidCode,
+				Msg:  UserCaptchaInvalid,
+			}, nil
+		case errors.Is(err, service.ErrUserPendingEmailVerification):
+			return Result{
+				Code: UserPendingEmailVerificationCode,
+				Msg:  UserCodeInvalid,
+			}, nil
+		case errors.Is(err, service.ErrUserFrozen):
+			return Result{
+				Code: UserFrozenCode,
+				Msg:  UserLoginFailure,
+			}, nil
+		case errors.Is(err, service.ErrUserBanned):
+			return Result{
+				Code: UserBannedCode,
+				Msg:  UserLoginFailure,
+			}, nil
+		case errors.Is(err, service.ErrUserWrittenOff):
+			return Result{
+				Code: UserWrittenOffCode,
+				Msg:  UserLoginFailure,
+			}, nil
+		}
+		return Result{
+			Code: UserServerErrorCode,
+		}, err
+	}
+	if err = uh.ijwt.SetLoginToken(ctx, du.ID); err != nil {
+		return Result{
+			Code: UserServerErrorCode,
+		}, err
+	}
+	return Result{
+		Code: RequestsOK,
+		Msg:  UserLoginSuccess,
+	}, nil
+}
+
+// loginFailKey 以邮箱+IP组合作为密码登录失败计数的维度
+func loginFailKey(ctx *gin.Context, email string) string {
+	return email + ":" + ctx.ClientIP()
 }
 
 func (uh *UserHandler) ListUser(ctx *gin.Context, req ListUserReq) (Result, error) {
@@ -343,6 +544,29 @@ func (uh *UserHandler) ListUser(ctx *gin.Context, req ListUserReq) (Result, erro
 	}, nil
 }
 
+// UpdateStatus 管理员变更指定用户的账号状态，由状态机校验迁移是否合法
+func (uh *UserHandler) UpdateStatus(ctx *gin.Context, req StatusReq) (Result, error) {
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	err := uh.svc.TransitionStatus(ctx, req.UID, uc.Uid, domain.UserStatus(req.TargetStatus), req.Reason)
+	if err != nil {
+		if errors.Is(err, service.ErrIllegalStatusTransition) {
+			return Result{
+				Code: UserIllegalStatusTransitionCode,
+				Msg:  UserIllegalStatusTransition,
+			}, nil
+		}
+		uh.l.Error("update user status failed", zap.Error(err))
+		return Result{
+			Code: UserServerErrorCode,
+			Msg:  UserLoginFailure,
+		}, err
+	}
+	return Result{
+		Code: RequestsOK,
+		Msg:  UserStatusUpdateSuccess,
+	}, nil
+}
+
 func (uh *UserHandler) GetUserCount(ctx *gin.Context, _ GetUserCountReq) (Result, error) {
 	count, err := uh.svc.GetUserCount(ctx)
 	if err != nil {
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	RankingGetTopNSuccess = "Ranking query success" // 热榜查询成功
+	RankingGetTopNError   = 503001                  // 热榜查询失败
+	RankingGetTopNErrMsg  = "Ranking query failed"  // 热榜查询失败提示
+)
@@ -0,0 +1,65 @@
+package constants
+
+// 通用请求状态码
+const (
+	RequestsOK  = 200 // 请求成功
+	ServerERROR = 500 // 服务器内部错误
+)
+
+// 用户模块状态码及提示信息
+const (
+	UserServerErrorCode           = 501001                   // 系统错误
+	UserSignUpSuccess             = "User sign up success"   // 注册成功
+	UserSignUpFailure             = "User sign up failed"    // 注册失败
+	UserEmailFormatErrorCode      = 501002                   // 邮箱格式错误
+	UserEmailFormatError          = "Invalid email format"   // 邮箱格式错误提示
+	UserPasswordMismatchErrorCode = 501003                   // 两次密码不一致
+	UserPasswordMismatchError     = "Password mismatch"      // 两次密码不一致提示
+	UserPasswordFormatErrorCode   = 501004                   // 密码格式错误
+	UserPasswordFormatError       = "Invalid password format"// 密码格式错误提示
+	UserEmailConflictErrorCode    = 501005                   // 邮箱冲突
+	UserEmailConflictError        = "Email already exists"   // 邮箱冲突提示
+	UserInvalidOrPasswordCode     = 501006                   // 账号或密码错误
+	UserLoginSuccess              = "User login success"     // 登录成功
+	UserLoginFailure              = "Invalid email or password" // 登录失败提示
+	UserLogoutFailure             = "User logout failed"     // 登出失败
+	UserLogoutSuccess             = "User logout success"    // 登出成功
+	UserRefreshTokenSuccess       = "Token refresh success"  // 刷新令牌成功
+	SMSNumberErr                  = 501007                   // 手机号格式错误
+	InvalidNumber                 = "Invalid phone number"   // 手机号格式错误提示
+	UserSendSMSCodeSuccess        = "SMS code sent"          // 短信验证码发送成功
+	UserInvalidInputCode          = 501008                   // 非法输入
+	UserPasswordChangeFailure     = "Password change failed" // 修改密码失败
+	UserPasswordChangeSuccess     = "Password change success"// 修改密码成功
+	UserSendEmailCodeSuccess      = "Email code sent"        // 邮箱验证码发送成功
+	UserDeletedFailure            = "User write off failed"  // 注销失败
+	UserDeletedSuccess            = "User write off success" // 注销成功
+	UserInvalidOrProfileErrorCode = 501009                   // 资料更新参数错误
+	UserProfileUpdateFailure      = "Profile update failed"  // 资料更新失败
+	UserProfileUpdateSuccess      = "Profile update success" // 资料更新成功
+	UserListErrorCode             = 501010                   // 用户列表查询失败
+	UserListError                 = "User list query failed"// 用户列表查询失败提示
+	UserListSuccess               = "User list query success"// 用户列表查询成功
+	UserGetCountErrorCode         = 501011                   // 用户数量查询失败
+	UserGetCountError             = "User count query failed"// 用户数量查询失败提示
+	UserGetCountSuccess           = "User count query success"// 用户数量查询成功
+
+	// 统一登录相关
+	UserUnsupportedGrantTypeCode = 501012                    // 不支持的登录方式
+	UserUnsupportedGrantType     = "Unsupported grant type"  // 不支持的登录方式提示
+	UserCodeInvalidCode          = 501013                    // 验证码错误
+	UserCodeInvalid              = "Verification code invalid or expired" // 验证码错误提示
+	UserCodeVerifyTooManyCode    = 501014                    // 验证码校验失败次数过多
+	UserCodeVerifyTooMany        = "Too many attempts, please request a new code" // 验证码尝试过多提示
+	UserCaptchaInvalidCode       = 501015                    // 验证码(人机验证)错误
+	UserCaptchaInvalid           = "Captcha invalid or required" // 人机验证错误提示
+
+	// 账号状态相关
+	UserPendingEmailVerificationCode = 501016 // 账号待邮箱验证
+	UserFrozenCode                   = 501017 // 账号已冻结
+	UserBannedCode                   = 501018 // 账号已封禁
+	UserWrittenOffCode               = 501019 // 账号已注销
+	UserIllegalStatusTransitionCode  = 501020 // 非法的状态迁移
+	UserIllegalStatusTransition      = "Illegal user status transition" // 非法状态迁移提示
+	UserStatusUpdateSuccess          = "User status update success"     // 状态更新成功
+)
@@ -0,0 +1,46 @@
+package userstatus
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicUserStatusChanged 用户状态变更事件的Kafka主题，供搜索索引、帖子可见性等下游系统订阅
+const TopicUserStatusChanged = "user_status_changed"
+
+// UserStatusChangedEvent 用户状态发生迁移时下发的事件
+type UserStatusChangedEvent struct {
+	UID        int64  `json:"uid"`
+	FromStatus string `json:"fromStatus"`
+	ToStatus   string `json:"toStatus"`
+	Reason     string `json:"reason"`
+	OperatorID int64  `json:"operatorId"`
+}
+
+// Producer 用户状态变更事件生产者
+type Producer interface {
+	ProduceStatusChanged(ctx context.Context, evt UserStatusChangedEvent) error
+}
+
+type saramaSyncProducer struct {
+	producer sarama.SyncProducer
+}
+
+// NewSaramaSyncProducer 基于 sarama 同步生产者创建 Producer
+func NewSaramaSyncProducer(producer sarama.SyncProducer) Producer {
+	return &saramaSyncProducer{producer: producer}
+}
+
+func (s *saramaSyncProducer) ProduceStatusChanged(ctx context.Context, evt UserStatusChangedEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicUserStatusChanged,
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
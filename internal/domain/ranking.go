@@ -0,0 +1,7 @@
+package domain
+
+// RankedItem 热榜中的一个条目
+type RankedItem struct {
+	BizID int64
+	Score float64
+}
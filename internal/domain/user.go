@@ -0,0 +1,41 @@
+package domain
+
+// UserStatus 用户生命周期状态
+type UserStatus string
+
+const (
+	UserStatusActive                   UserStatus = "active"                      // 正常
+	UserStatusPendingEmailVerification UserStatus = "pending_email_verification"  // 待邮箱验证
+	UserStatusFrozen                   UserStatus = "frozen"                      // 已冻结
+	UserStatusBanned                   UserStatus = "banned"                      // 已封禁
+	UserStatusWrittenOff               UserStatus = "written_off"                 // 已注销（终态）
+)
+
+// User 用户领域对象
+type User struct {
+	ID           int64
+	Email        string
+	Phone        string
+	Password     string
+	Status       UserStatus
+	StatusReason string
+	CreateTime   int64
+	UpdatedTime  int64
+	Deleted      bool
+	Profile      Profile
+}
+
+// Profile 用户详情信息
+type Profile struct {
+	UserID   int64
+	NickName string
+	Avatar   string
+	About    string
+	Birthday string
+}
+
+// Pagination 分页参数
+type Pagination struct {
+	Page int
+	Size int
+}
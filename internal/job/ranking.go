@@ -0,0 +1,109 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GoSimplicity/LinkMe/internal/repository/dao"
+	"github.com/GoSimplicity/LinkMe/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RankingExecutorName 是热榜任务在 Job.Executor 中登记的名字；Preempt 不按 Executor 过滤任务，Run 也不检查该字段
+const RankingExecutorName = "ranking"
+
+// leaseRenewInterval 续约节奏应明显小于抢占时申请的租约时长，避免因网络抖动错过续约窗口
+const leaseRenewInterval = 10 * time.Second
+
+// RankingExecutor 每次 Run 抢占一个到期任务并驱动 RankingService 执行一次计算，
+// 复用 JobDAO 的租约协议来保证单实例执行与崩溃恢复
+type RankingExecutor struct {
+	jobDAO   dao.JobDAO
+	ranking  service.RankingService
+	workerID string
+	leaseMs  int64
+	interval time.Duration // 对应 RankingConfig.Interval，成功执行一次后据此推迟下一次 next_time，形成周期调度
+	l        *zap.Logger
+}
+
+func NewRankingExecutor(jobDAO dao.JobDAO, ranking service.RankingService, workerID string, leaseMs int64, interval time.Duration, l *zap.Logger) *RankingExecutor {
+	return &RankingExecutor{
+		jobDAO:   jobDAO,
+		ranking:  ranking,
+		workerID: workerID,
+		leaseMs:  leaseMs,
+		interval: interval,
+		l:        l,
+	}
+}
+
+// jobName 为每个biz生成独立的 Job.Name，使各biz的热榜计算拥有互不干扰的调度记录
+func jobName(biz string) string {
+	return RankingExecutorName + ":" + biz
+}
+
+// EnsureRegistered 幂等注册 biz 对应的 ranking Job 行，供启动时调用以补齐调度器需要的 Job 记录；
+// 已注册过的 biz 不会被重复插入或重置状态
+func (e *RankingExecutor) EnsureRegistered(ctx context.Context, biz string) error {
+	return e.jobDAO.Register(ctx, dao.Job{
+		Name:       jobName(biz),
+		Executor:   RankingExecutorName,
+		Expression: e.interval.String(),
+		Cfg:        biz,
+		NextTime:   time.Now().UnixMilli(),
+	})
+}
+
+// Run 抢占一次到期任务（不区分 Executor）并执行热榜计算，biz 取自 Job.Cfg；没有到期任务时返回 nil 而非错误。
+// 执行成功后按 interval 推迟 next_time，形成基于 JobDAO 租约协议的周期调度
+func (e *RankingExecutor) Run(ctx context.Context) error {
+	j, err := e.jobDAO.Preempt(ctx, e.workerID, e.leaseMs)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	runCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	go e.keepRenewing(runCtx, abort, j.Id, j.Version)
+
+	biz := j.Cfg
+	if err = e.ranking.Rank(runCtx, biz); err != nil {
+		e.l.Error("ranking job failed", zap.String("biz", biz), zap.Error(err))
+		if reportErr := e.jobDAO.ReportFailure(ctx, j.Id, e.workerID, err); reportErr != nil && !errors.Is(reportErr, gorm.ErrRecordNotFound) {
+			e.l.Error("report ranking job failure failed", zap.Error(reportErr))
+		}
+		return err
+	}
+	if releaseErr := e.jobDAO.Release(ctx, j.Id, e.workerID); releaseErr != nil && !errors.Is(releaseErr, gorm.ErrRecordNotFound) {
+		return releaseErr
+	}
+	if err = e.jobDAO.UpdateNextTime(ctx, j.Id, time.Now().Add(e.interval)); err != nil {
+		e.l.Error("schedule next ranking run failed", zap.String("biz", biz), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// keepRenewing 定期续约；一旦续约失败（租约已被他人抢占），立即调用 abort 取消运行上下文，
+// 使 Rank 尽快感知 ctx.Done 并中止，避免租约丢失后仍继续执行并与新持有者的执行互相覆盖
+func (e *RankingExecutor) keepRenewing(ctx context.Context, abort context.CancelFunc, id int64, version int) {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.jobDAO.Renew(ctx, id, version, e.leaseMs); err != nil {
+				e.l.Error("renew ranking job lease failed, aborting run", zap.Int64("id", id), zap.Error(err))
+				abort()
+				return
+			}
+		}
+	}
+}
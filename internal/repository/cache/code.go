@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCodeVerifyTooManyTimes 验证码校验失败次数过多，视作验证码已失效
+var ErrCodeVerifyTooManyTimes = errors.New("verify code too many times")
+
+const (
+	// defaultCodeTTL 验证码默认有效期，超时未校验自动失效
+	defaultCodeTTL = 10 * time.Minute
+	// defaultMaxAttempts 默认允许的校验失败次数
+	defaultMaxAttempts = 3
+)
+
+// verifyCodeLuaScript 原子地校验验证码：
+// 验证码不存在或已过期 -> -1
+// 校验失败次数超限       -> -2
+// 答案不匹配            -> 0，并自增失败次数
+// 答案匹配              -> 1，并删除验证码（一次性）
+// cntKey 的有效期需要与验证码本身对齐，否则过期的失败计数会在 key 被删除后仍然遗留（永久占用内存），
+// 且一旦计数先于验证码过期就会出现"计数清零但验证码仍可用"的窗口；因此每次incr都续期到 ttlMs
+const verifyCodeLuaScript = `
+local key = KEYS[1]
+local cntKey = key .. ":cnt"
+local expectedCode = ARGV[1]
+local maxAttempts = tonumber(ARGV[2])
+local ttlMs = tonumber(ARGV[3])
+local code = redis.call("get", key)
+local cnt = tonumber(redis.call("get", cntKey) or "0")
+if code == false then
+	return -1
+end
+if cnt >= maxAttempts then
+	return -2
+end
+if code == expectedCode then
+	redis.call("del", key)
+	redis.call("del", cntKey)
+	return 1
+end
+redis.call("incr", cntKey)
+redis.call("pexpire", cntKey, ttlMs)
+return 0
+`
+
+// CodeCache 对接 smsProducer/emailProducer 消费者写入的验证码，供登录/注册等场景校验
+type CodeCache interface {
+	// Set 写入一个验证码，biz 区分业务场景，target 为手机号或邮箱
+	Set(ctx context.Context, biz, target, code string) error
+	// Verify 校验验证码，校验通过后验证码立即失效（一次性）
+	Verify(ctx context.Context, biz, target, code string) (bool, error)
+}
+
+type codeCache struct {
+	client      redis.Cmdable
+	ttl         time.Duration
+	maxAttempts int
+}
+
+// NewCodeCache 创建验证码缓存，ttl<=0 时使用默认有效期，maxAttempts<=0 时使用默认失败锁定次数
+func NewCodeCache(client redis.Cmdable, ttl time.Duration, maxAttempts int) CodeCache {
+	if ttl <= 0 {
+		ttl = defaultCodeTTL
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &codeCache{client: client, ttl: ttl, maxAttempts: maxAttempts}
+}
+
+func (c *codeCache) key(biz, target string) string {
+	return fmt.Sprintf("code:%s:%s", biz, target)
+}
+
+// Set 写入新验证码的同时清空失败计数，确保重新发码会给用户一个全新的尝试次数预算，
+// 而不是延续此前已锁定（或接近锁定）的 :cnt
+func (c *codeCache) Set(ctx context.Context, biz, target, code string) error {
+	key := c.key(biz, target)
+	if err := c.client.Set(ctx, key, code, c.ttl).Err(); err != nil {
+		return err
+	}
+	return c.client.Del(ctx, key+":cnt").Err()
+}
+
+func (c *codeCache) Verify(ctx context.Context, biz, target, code string) (bool, error) {
+	key := c.key(biz, target)
+	res, err := c.client.Eval(ctx, verifyCodeLuaScript, []string{key}, code, c.maxAttempts, c.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	switch res {
+	case -1:
+		return false, nil
+	case -2:
+		return false, ErrCodeVerifyTooManyTimes
+	case 1:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// loginFailTTL 登录失败计数的有效期，自第一次失败起计时，到期后计数清零；之后的失败不会延长有效期
+const loginFailTTL = 10 * time.Minute
+
+// LoginFailCache 记录同一邮箱/IP的连续登录失败次数，用于决定是否需要人机验证
+type LoginFailCache interface {
+	// Incr 记录一次失败，返回自增后的失败次数
+	Incr(ctx context.Context, key string) (int64, error)
+	// Peek 读取当前失败次数，不产生副作用
+	Peek(ctx context.Context, key string) (int64, error)
+	// Reset 登录成功后清空失败计数
+	Reset(ctx context.Context, key string) error
+}
+
+type loginFailCache struct {
+	client redis.Cmdable
+}
+
+func NewLoginFailCache(client redis.Cmdable) LoginFailCache {
+	return &loginFailCache{client: client}
+}
+
+func (c *loginFailCache) cacheKey(key string) string {
+	return fmt.Sprintf("login_fail:%s", key)
+}
+
+func (c *loginFailCache) Incr(ctx context.Context, key string) (int64, error) {
+	cacheKey := c.cacheKey(key)
+	cnt, err := c.client.Incr(ctx, cacheKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if cnt == 1 {
+		if err = c.client.Expire(ctx, cacheKey, loginFailTTL).Err(); err != nil {
+			return cnt, err
+		}
+	}
+	return cnt, nil
+}
+
+func (c *loginFailCache) Peek(ctx context.Context, key string) (int64, error) {
+	cnt, err := c.client.Get(ctx, c.cacheKey(key)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return cnt, nil
+}
+
+func (c *loginFailCache) Reset(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.cacheKey(key)).Err()
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoSimplicity/LinkMe/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// RankingCache 热榜快照存储：Redis ZSET 作为多实例共享的真相源，本地缓存加速高频读取
+type RankingCache interface {
+	// Set 原子地用一份全新快照替换某个biz的热榜
+	Set(ctx context.Context, biz string, items []domain.RankedItem) error
+	// GetTopN 优先读取本地缓存，未命中或已过期则回源 Redis ZSET
+	GetTopN(ctx context.Context, biz string, n int) ([]domain.RankedItem, error)
+}
+
+type localEntry struct {
+	items     []domain.RankedItem
+	expiresAt time.Time
+}
+
+type rankingCache struct {
+	client redis.Cmdable
+	ttl    time.Duration
+
+	mu    sync.RWMutex
+	local map[string]localEntry
+}
+
+// NewRankingCache 创建热榜缓存，ttl 为本地缓存的过期时间
+func NewRankingCache(client redis.Cmdable, ttl time.Duration) RankingCache {
+	return &rankingCache{
+		client: client,
+		ttl:    ttl,
+		local:  make(map[string]localEntry),
+	}
+}
+
+func (c *rankingCache) key(biz string) string {
+	return fmt.Sprintf("ranking:%s", biz)
+}
+
+func (c *rankingCache) Set(ctx context.Context, biz string, items []domain.RankedItem) error {
+	key := c.key(biz)
+	members := make([]redis.Z, 0, len(items))
+	for _, it := range items {
+		members = append(members, redis.Z{Score: it.Score, Member: it.BizID})
+	}
+	// 用临时key写入新快照再原子改名，避免清空旧key后读到空结果的窗口期
+	tmpKey := key + ":staging"
+	pipe := c.client.TxPipeline()
+	pipe.Del(ctx, tmpKey)
+	if len(members) > 0 {
+		pipe.ZAdd(ctx, tmpKey, members...)
+		pipe.Rename(ctx, tmpKey, key)
+	} else {
+		// 空快照不会创建临时key，无法改名，直接删除旧榜单
+		pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.local[biz] = localEntry{items: items, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *rankingCache) GetTopN(ctx context.Context, biz string, n int) ([]domain.RankedItem, error) {
+	c.mu.RLock()
+	entry, ok := c.local[biz]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return truncate(entry.items, n), nil
+	}
+
+	res, err := c.client.ZRevRangeWithScores(ctx, c.key(biz), 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.RankedItem, 0, len(res))
+	for _, z := range res {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		bizID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, domain.RankedItem{BizID: bizID, Score: z.Score})
+	}
+
+	c.mu.Lock()
+	c.local[biz] = localEntry{items: items, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return items, nil
+}
+
+func truncate(items []domain.RankedItem, n int) []domain.RankedItem {
+	if len(items) <= n {
+		return items
+	}
+	return items[:n]
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowLuaScript 基于 ZSET 的滑动窗口限流：清理窗口外的计数，若窗口内计数未超限则记录本次请求
+// 并返回1（放行），否则返回0（超限）。Redis 在每次脚本执行前都会将 Lua 的 math.random 重新播种到固定种子，
+// 同一毫秒内的多次调用会得到完全相同的随机数，导致 ZSET member 碰撞、zadd 变为no-op从而漏计数，
+// 因此改用 key 自带的自增序列号保证 member 唯一
+const slidingWindowLuaScript = `
+local key = KEYS[1]
+local windowMs = tonumber(ARGV[1])
+local threshold = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call("zremrangebyscore", key, 0, now - windowMs)
+local cnt = redis.call("zcard", key)
+if cnt >= threshold then
+	redis.call("pexpire", key, windowMs)
+	return 0
+end
+local seq = redis.call("incr", key .. ":seq")
+redis.call("zadd", key, now, now .. "-" .. seq)
+redis.call("pexpire", key, windowMs)
+redis.call("pexpire", key .. ":seq", windowMs)
+return 1
+`
+
+// RateLimiter 基于Redis的滑动窗口限流器，用于判断某个key（如IP/邮箱）是否触发了验证码门槛
+type RateLimiter interface {
+	// Allow 未达阈值时在窗口内记录本次请求并返回true；已达阈值时不记录，返回false
+	Allow(ctx context.Context, key string, window time.Duration, threshold int) (bool, error)
+}
+
+type rateLimiter struct {
+	client redis.Cmdable
+}
+
+func NewRateLimiter(client redis.Cmdable) RateLimiter {
+	return &rateLimiter{client: client}
+}
+
+func (r *rateLimiter) Allow(ctx context.Context, key string, window time.Duration, threshold int) (bool, error) {
+	now := time.Now().UnixMilli()
+	res, err := r.client.Eval(ctx, slidingWindowLuaScript,
+		[]string{fmt.Sprintf("ratelimit:%s", key)},
+		window.Milliseconds(), threshold, now,
+	).Int64()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
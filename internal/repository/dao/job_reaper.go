@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// JobReaper 周期性巡检已过期但仍处于运行态的任务租约（对应崩溃的worker），仅负责可观测性上报，
+// 真正的回收发生在下一次 Preempt：其 WHERE 条件本就会重新认领这些任务
+type JobReaper struct {
+	db       *gorm.DB
+	l        *zap.Logger
+	interval time.Duration
+}
+
+// NewJobReaper 创建一个租约巡检器，interval<=0 时使用默认巡检间隔
+func NewJobReaper(db *gorm.DB, l *zap.Logger, interval time.Duration) *JobReaper {
+	if interval <= 0 {
+		interval = defaultReaperInterval
+	}
+	return &JobReaper{db: db, l: l, interval: interval}
+}
+
+// Start 启动巡检goroutine，直到 ctx 被取消
+func (r *JobReaper) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.reapOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (r *JobReaper) reapOnce(ctx context.Context) {
+	now := time.Now().UnixMilli()
+	var expired []Job
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND lease_until < ?", jobStatusRunning, now).
+		Find(&expired).Error
+	if err != nil {
+		r.l.Error("reaper query expired leases failed", zap.Error(err))
+		return
+	}
+	if len(expired) == 0 {
+		return
+	}
+	r.l.Warn("recovered jobs with expired lease", zap.Int("count", len(expired)))
+	for _, j := range expired {
+		r.l.Info("job lease expired, eligible for re-preemption",
+			zap.Int64("id", j.Id),
+			zap.String("name", j.Name),
+			zap.String("lease_owner", j.LeaseOwner),
+			zap.Int64("lease_until", j.LeaseUntil),
+		)
+	}
+}
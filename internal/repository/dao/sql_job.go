@@ -2,8 +2,12 @@ package dao
 
 import (
 	"context"
-	"gorm.io/gorm"
+	"math"
+	"math/rand"
 	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 const (
@@ -13,21 +17,43 @@ const (
 	jobStatusRunning
 	// jobStatusPaused 任务已暂停
 	jobStatusPaused
+	// jobStatusDead 任务已进入死信状态，不再被调度
+	jobStatusDead
 )
 
-// JobDAO 定义了任务数据访问对象接口
+const (
+	// defaultMaxRetryCount 默认的最大重试次数，失败次数达到该值后任务进入死信状态
+	defaultMaxRetryCount = 5
+	// defaultReaperInterval 默认的租约巡检间隔
+	defaultReaperInterval = 10 * time.Second
+)
+
+// JobDAO 定义了任务数据访问对象接口，基于租约协议实现抢占与故障恢复
 type JobDAO interface {
-	Preempt(ctx context.Context) (Job, error)
-	Release(ctx context.Context, jobId int64) error
+	// Preempt 以 workerID 的身份抢占一个可调度的任务并持有 leaseMs 时长的租约
+	Preempt(ctx context.Context, workerID string, leaseMs int64) (Job, error)
+	// Renew 续约，workerID 持有的租约需定期调用，version 不匹配说明租约已被他人抢占
+	Renew(ctx context.Context, id int64, version int, extendMs int64) error
+	// Release 主动释放任务，恢复为等待状态；leaseOwner 必须与当前持有者一致，避免释放已被他人抢占的任务
+	Release(ctx context.Context, jobId int64, leaseOwner string) error
+	// ReportFailure 上报一次执行失败，按退避策略安排下一次执行时间，失败次数达到最大重试次数后进入死信状态；
+	// leaseOwner 必须与当前持有者一致，避免租约已被他人抢占后仍旧更新该任务
+	ReportFailure(ctx context.Context, id int64, leaseOwner string, execErr error) error
 	UpdateTime(ctx context.Context, id int64) error
 	UpdateNextTime(ctx context.Context, id int64, t time.Time) error
+	// Register 按 Name 幂等注册一条任务：已存在则不做改动，不存在则以等待状态插入，
+	// 供启动时为周期性任务（如热榜计算）补齐 Job 行使用
+	Register(ctx context.Context, j Job) error
 }
 
 // jobDAO 实现了 JobDAO 接口
 type jobDAO struct {
-	db *gorm.DB
+	db            *gorm.DB
+	l             *zap.Logger
+	maxRetryCount int
 }
 
+// Job 任务实体，lease_owner/lease_until 实现租约协议，崩溃的 worker 租约到期后可被其他 worker 抢占
 type Job struct {
 	Id          int64  `gorm:"primaryKey,autoIncrement"`               // 任务ID，主键，自增
 	Name        string `gorm:"type:varchar(128);unique"`               // 任务名称，唯一
@@ -36,53 +62,153 @@ type Job struct {
 	Cfg         string `gorm:"type:text"`                              // 配置，任务的具体配置信息
 	Status      int    `gorm:"type:int"`                               // 任务状态，用于标识任务当前的状态（如启用、禁用等）
 	Version     int    `gorm:"type:int"`                               // 版本号，用于乐观锁控制并发更新
-	NextTime    int64  `gorm:"index"`                                  // 下次执行时间，Unix时间戳
+	NextTime    int64  `gorm:"index"`                                  // 下次执行时间，Unix时间戳（毫秒）
+	LeaseOwner  string `gorm:"type:varchar(128);index"`                // 当前持有租约的worker ID
+	LeaseUntil  int64  `gorm:"index"`                                  // 租约到期时间，Unix时间戳（毫秒），运行态任务的租约超过该时间视为worker已崩溃
+	RetryCount  int    `gorm:"type:int"`                               // 连续失败次数，用于退避与死信判定
 	CreateTime  int64  `gorm:"column:created_at;type:bigint;not null"` // 创建时间，Unix时间戳
 	UpdatedTime int64  `gorm:"column:updated_at;type:bigint;not null"` // 更新时间，Unix时间戳
 }
 
-// NewJobDAO 创建并初始化 jobDAO 实例
-func NewJobDAO(db *gorm.DB) JobDAO {
+// NewJobDAO 创建并初始化 jobDAO 实例，maxRetryCount<=0 时使用默认值
+func NewJobDAO(db *gorm.DB, l *zap.Logger, maxRetryCount int) JobDAO {
+	if maxRetryCount <= 0 {
+		maxRetryCount = defaultMaxRetryCount
+	}
 	return &jobDAO{
-		db: db,
+		db:            db,
+		l:             l,
+		maxRetryCount: maxRetryCount,
 	}
 }
 
-// Preempt 抢占一个等待状态的任务
-func (dao *jobDAO) Preempt(ctx context.Context) (Job, error) {
+// Preempt 抢占一个可调度的任务：要么处于等待状态，要么处于运行状态但租约已过期（worker崩溃）
+// 为避免热点worker持续抢占同一批任务导致其他worker饿死，按 next_time 升序逐个尝试认领
+func (dao *jobDAO) Preempt(ctx context.Context, workerID string, leaseMs int64) (Job, error) {
 	db := dao.db.WithContext(ctx)
 	for {
 		var j Job
 		now := time.Now().UnixMilli()
-		// 查找一个等待状态且下一次执行时间小于当前时间的任务
-		err := db.Where("status = ? AND next_time < ?", jobStatusWaiting, now).First(&j).Error
+		err := db.Where("(status = ? AND next_time < ?) OR (status = ? AND lease_until < ?)", jobStatusWaiting, now, jobStatusRunning, now).
+			Order("next_time ASC").
+			First(&j).Error
 		if err != nil {
-			return j, err
+			return Job{}, err
 		}
-		// 尝试更新任务的状态和版本
 		result := db.Model(&Job{}).Where("id = ? AND version = ?", j.Id, j.Version).Updates(map[string]any{
 			"status":     jobStatusRunning,
 			"version":    j.Version + 1,
+			"lease_owner": workerID,
+			"lease_until": now + leaseMs,
 			"updated_at": now,
 		})
 		if result.Error != nil {
 			return Job{}, result.Error
 		}
 		if result.RowsAffected == 0 {
-			// 如果没有抢到任务，继续循环
+			// 被其他worker抢先，继续尝试下一个候选
 			continue
 		}
+		j.Version++
+		j.LeaseOwner = workerID
+		j.LeaseUntil = now + leaseMs
 		return j, nil
 	}
 }
 
-// Release 释放一个正在运行的任务，将其状态重置为等待状态
-func (dao *jobDAO) Release(ctx context.Context, jobId int64) error {
+// Renew 续约，worker需在租约到期前定期调用；version不匹配说明租约已被判定过期并被其他worker抢占，调用方必须中止当前执行
+func (dao *jobDAO) Renew(ctx context.Context, id int64, version int, extendMs int64) error {
 	now := time.Now().UnixMilli()
-	return dao.db.WithContext(ctx).Model(&Job{}).Where("id = ?", jobId).Updates(map[string]any{
-		"status":     jobStatusWaiting,
-		"updated_at": now,
-	}).Error
+	result := dao.db.WithContext(ctx).Model(&Job{}).Where("id = ? AND version = ?", id, version).Updates(map[string]any{
+		"lease_until": now + extendMs,
+		"updated_at":  now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Release 释放一个正在运行的任务，将其状态重置为等待状态并清空租约；leaseOwner 不匹配（租约已被他人抢占）时
+// RowsAffected 为 0，返回 gorm.ErrRecordNotFound，调用方不得将其当作释放成功处理
+func (dao *jobDAO) Release(ctx context.Context, jobId int64, leaseOwner string) error {
+	now := time.Now().UnixMilli()
+	result := dao.db.WithContext(ctx).Model(&Job{}).Where("id = ? AND lease_owner = ?", jobId, leaseOwner).Updates(map[string]any{
+		"status":      jobStatusWaiting,
+		"lease_owner": "",
+		"lease_until": 0,
+		"updated_at":  now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReportFailure 累加重试次数并按指数退避+抖动安排下一次执行时间；重试次数达到最大值后进入死信状态不再被调度。
+// leaseOwner 不匹配（租约已被他人抢占）时返回 gorm.ErrRecordNotFound，不得更新已属于新持有者的任务
+func (dao *jobDAO) ReportFailure(ctx context.Context, id int64, leaseOwner string, execErr error) error {
+	db := dao.db.WithContext(ctx)
+	var j Job
+	if err := db.Where("id = ? AND lease_owner = ?", id, leaseOwner).First(&j).Error; err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	retryCount := j.RetryCount + 1
+	if dao.l != nil {
+		dao.l.Warn("job execution failed", zap.Int64("id", id), zap.Int("retry_count", retryCount), zap.Error(execErr))
+	}
+	if retryCount >= dao.maxRetryCount {
+		result := db.Model(&Job{}).Where("id = ? AND lease_owner = ?", id, leaseOwner).Updates(map[string]any{
+			"status":      jobStatusDead,
+			"retry_count": retryCount,
+			"lease_owner": "",
+			"lease_until": 0,
+			"updated_at":  now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}
+	nextTime := now + backoffWithJitter(retryCount)
+	result := db.Model(&Job{}).Where("id = ? AND lease_owner = ?", id, leaseOwner).Updates(map[string]any{
+		"status":      jobStatusWaiting,
+		"retry_count": retryCount,
+		"next_time":   nextTime,
+		"lease_owner": "",
+		"lease_until": 0,
+		"updated_at":  now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// backoffWithJitter 计算第 retryCount 次重试的退避时长（毫秒），指数退避值 backoff 叠加 [0, backoff] 的随机抖动以避免惊群，
+// 结果落在 [backoff, 2*backoff]
+func backoffWithJitter(retryCount int) int64 {
+	const baseMs = 1000
+	const maxMs = 5 * 60 * 1000
+	backoff := float64(baseMs) * math.Pow(2, float64(retryCount-1))
+	if backoff > float64(maxMs) {
+		backoff = float64(maxMs)
+	}
+	jitter := rand.Int63n(int64(backoff) + 1)
+	return int64(backoff) + jitter
 }
 
 // UpdateTime 更新任务的更新时间
@@ -101,3 +227,11 @@ func (dao *jobDAO) UpdateNextTime(ctx context.Context, id int64, t time.Time) er
 		"next_time":  t.UnixMilli(),
 	}).Error
 }
+
+// Register 按 Name 幂等注册任务，已存在的行不被覆盖，避免重复启动时重置正在运行任务的状态/租约
+func (dao *jobDAO) Register(ctx context.Context, j Job) error {
+	now := time.Now().UnixMilli()
+	j.CreateTime = now
+	j.UpdatedTime = now
+	return dao.db.WithContext(ctx).Where(Job{Name: j.Name}).FirstOrCreate(&j).Error
+}
@@ -0,0 +1,174 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestJobDAO(t *testing.T) (*jobDAO, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared&_busy_timeout=5000"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Job{}))
+	dao := NewJobDAO(db, zap.NewNop(), 3).(*jobDAO)
+	return dao, db
+}
+
+func TestJobDAO_Preempt_ReclaimsExpiredLease(t *testing.T) {
+	dao, db := newTestJobDAO(t)
+	ctx := context.Background()
+
+	now := time.Now().UnixMilli()
+	require.NoError(t, db.Create(&Job{
+		Name:       "crashed-job",
+		Status:     jobStatusRunning,
+		Version:    1,
+		NextTime:   now - 1000,
+		LeaseOwner: "worker-dead",
+		LeaseUntil: now - 500, // 租约已过期，模拟worker崩溃
+	}).Error)
+
+	j, err := dao.Preempt(ctx, "worker-alive", 30_000)
+	require.NoError(t, err)
+	assert.Equal(t, "crashed-job", j.Name)
+	assert.Equal(t, "worker-alive", j.LeaseOwner)
+
+	var reloaded Job
+	require.NoError(t, db.Where("name = ?", "crashed-job").First(&reloaded).Error)
+	assert.Equal(t, "worker-alive", reloaded.LeaseOwner)
+	assert.Equal(t, jobStatusRunning, reloaded.Status)
+}
+
+func TestJobDAO_Preempt_ConcurrentWorkersClaimDistinctJobs(t *testing.T) {
+	dao, db := newTestJobDAO(t)
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	const jobCount = 10
+	for i := 0; i < jobCount; i++ {
+		require.NoError(t, db.Create(&Job{
+			Name:     "job-" + string(rune('a'+i)),
+			Status:   jobStatusWaiting,
+			Version:  1,
+			NextTime: now - int64(jobCount-i),
+		}).Error)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		claimed int64
+		mu      sync.Mutex
+		seen    = map[int64]bool{}
+	)
+	for w := 0; w < jobCount; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			j, err := dao.Preempt(ctx, "worker", 30_000)
+			if err != nil {
+				return
+			}
+			atomic.AddInt64(&claimed, 1)
+			mu.Lock()
+			defer mu.Unlock()
+			require.False(t, seen[j.Id], "job %d claimed by more than one worker", j.Id)
+			seen[j.Id] = true
+		}(w)
+	}
+	wg.Wait()
+
+	// sqlite 在高并发写入下即便设置了 busy_timeout 仍可能对个别请求返回 SQLITE_BUSY，
+	// 因此这里只断言"已认领的任务各不相同"这一核心不变量，而非精确的认领总数
+	assert.Greater(t, claimed, int64(0), "at least some workers should have claimed a job")
+	assert.LessOrEqual(t, claimed, int64(jobCount))
+	assert.EqualValues(t, claimed, len(seen), "claimed jobs must all be distinct")
+}
+
+func TestJobDAO_Renew_FailsAfterLeaseReassigned(t *testing.T) {
+	dao, db := newTestJobDAO(t)
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	require.NoError(t, db.Create(&Job{
+		Name:     "renew-job",
+		Status:   jobStatusRunning,
+		Version:  1,
+		NextTime: now - 1000,
+	}).Error)
+
+	j, err := dao.Preempt(ctx, "worker-1", 30_000)
+	require.NoError(t, err)
+
+	require.NoError(t, dao.Renew(ctx, j.Id, j.Version, 30_000))
+
+	// 模拟version被其他事务改变后（例如租约过期被他人抢占），续约必须失败
+	require.NoError(t, db.Model(&Job{}).Where("id = ?", j.Id).Update("version", j.Version+1).Error)
+	err = dao.Renew(ctx, j.Id, j.Version, 30_000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestJobDAO_ReportFailure_DeadLettersAfterMaxRetry(t *testing.T) {
+	dao, db := newTestJobDAO(t)
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	require.NoError(t, db.Create(&Job{
+		Name:     "flaky-job",
+		Status:   jobStatusRunning,
+		Version:  1,
+		NextTime: now,
+	}).Error)
+	var j Job
+	require.NoError(t, db.Where("name = ?", "flaky-job").First(&j).Error)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, dao.ReportFailure(ctx, j.Id, j.LeaseOwner, assert.AnError))
+		var reloaded Job
+		require.NoError(t, db.Where("id = ?", j.Id).First(&reloaded).Error)
+		assert.Equal(t, jobStatusWaiting, reloaded.Status)
+	}
+
+	require.NoError(t, dao.ReportFailure(ctx, j.Id, j.LeaseOwner, assert.AnError))
+	var dead Job
+	require.NoError(t, db.Where("id = ?", j.Id).First(&dead).Error)
+	assert.Equal(t, jobStatusDead, dead.Status)
+	assert.Equal(t, 3, dead.RetryCount)
+}
+
+func TestJobDAO_Release_RejectsStaleLeaseOwner(t *testing.T) {
+	dao, db := newTestJobDAO(t)
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	require.NoError(t, db.Create(&Job{
+		Name:     "stolen-job",
+		Status:   jobStatusWaiting,
+		Version:  1,
+		NextTime: now - 1000,
+	}).Error)
+
+	j, err := dao.Preempt(ctx, "worker-1", 1) // 租约1ms，立刻过期
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	stolen, err := dao.Preempt(ctx, "worker-2", 30_000)
+	require.NoError(t, err)
+	assert.Equal(t, j.Id, stolen.Id)
+
+	// worker-1 的租约已被 worker-2 抢占，其 Release/ReportFailure 不得再生效
+	err = dao.Release(ctx, j.Id, "worker-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var reloaded Job
+	require.NoError(t, db.Where("id = ?", j.Id).First(&reloaded).Error)
+	assert.Equal(t, "worker-2", reloaded.LeaseOwner)
+	assert.Equal(t, jobStatusRunning, reloaded.Status)
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "context"
+
+// RankingCandidateRepository 提供热榜算法所需的候选帖子ID，由具体业务仓储（如帖子仓储）实现
+type RankingCandidateRepository interface {
+	// ListCandidateIDs 按 biz 分批列出参与热榜计算的候选ID，offset/limit 用于流式分页，避免一次性加载全部候选
+	ListCandidateIDs(ctx context.Context, biz string, offset, limit int) ([]int64, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/GoSimplicity/LinkMe/internal/domain"
+)
+
+// UserRepository 定义了用户数据访问的仓储接口
+type UserRepository interface {
+	Create(ctx context.Context, u domain.User) error
+	FindByEmail(ctx context.Context, email string) (domain.User, error)
+	FindByPhone(ctx context.Context, phone string) (domain.User, error)
+	FindByID(ctx context.Context, id int64) (domain.User, error)
+	UpdatePassword(ctx context.Context, email string, newPassword string) error
+	UpdateProfile(ctx context.Context, profile domain.Profile) error
+	UpdateStatus(ctx context.Context, uid int64, status domain.UserStatus, reason string) error
+	DeleteUser(ctx context.Context, email string, uid int64) error
+	List(ctx context.Context, pagination domain.Pagination) ([]domain.User, error)
+	Count(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+
+	"github.com/GoSimplicity/LinkMe/internal/domain"
+	"github.com/GoSimplicity/LinkMe/internal/repository"
+	"github.com/GoSimplicity/LinkMe/internal/repository/cache"
+	"go.uber.org/zap"
+)
+
+// RankingWeights 打分权重，对应点赞/收藏/阅读对热度的贡献
+type RankingWeights struct {
+	Like    float64
+	Collect float64
+	Read    float64
+}
+
+// RankingConfig 热榜计算参数，均从配置加载，便于运维在不重新编译的情况下调参
+type RankingConfig struct {
+	TopN          int           // 每个biz保留的热榜条目数
+	BatchSize     int           // 每批拉取的候选ID数量
+	Interval      time.Duration // 定时计算的触发间隔
+	Weights       RankingWeights
+	DecayExponent float64 // 时间衰减的指数，越大衰减越快
+	CacheTTL      time.Duration
+}
+
+// RankingService 热榜服务：基于 InteractiveService.GetByIds 的互动数据计算类 Hacker News 的热度分
+type RankingService interface {
+	// Rank 对指定biz执行一次完整的热榜计算并落盘到缓存
+	Rank(ctx context.Context, biz string) error
+	// GetTopN 返回指定biz当前的热榜前N，n<=0 时返回配置中的 TopN
+	GetTopN(ctx context.Context, biz string, n int) ([]domain.RankedItem, error)
+}
+
+type rankingService struct {
+	candidateRepo repository.RankingCandidateRepository
+	interactive   InteractiveService
+	cache         cache.RankingCache
+	cfg           RankingConfig
+	l             *zap.Logger
+}
+
+func NewRankingService(candidateRepo repository.RankingCandidateRepository, interactive InteractiveService, rankingCache cache.RankingCache, cfg RankingConfig, l *zap.Logger) RankingService {
+	return &rankingService{
+		candidateRepo: candidateRepo,
+		interactive:   interactive,
+		cache:         rankingCache,
+		cfg:           cfg,
+		l:             l,
+	}
+}
+
+// Rank 流式扫描候选ID、批量查询互动数据并打分，用大小为TopN的最小堆维持候选集，内存占用恒为 O(TopN)
+func (svc *rankingService) Rank(ctx context.Context, biz string) error {
+	h := &rankedItemHeap{}
+	heap.Init(h)
+	now := time.Now()
+
+	for offset := 0; ; offset += svc.cfg.BatchSize {
+		ids, err := svc.candidateRepo.ListCandidateIDs(ctx, biz, offset, svc.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		interactives, err := svc.interactive.GetByIds(ctx, biz, ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			di, ok := interactives[id]
+			if !ok {
+				continue
+			}
+			score := svc.score(di, now)
+			item := domain.RankedItem{BizID: id, Score: score}
+			if h.Len() < svc.cfg.TopN {
+				heap.Push(h, item)
+			} else if h.Len() > 0 && score > (*h)[0].Score {
+				heap.Pop(h)
+				heap.Push(h, item)
+			}
+		}
+		if len(ids) < svc.cfg.BatchSize {
+			break
+		}
+	}
+
+	items := h.sorted()
+	if svc.l != nil {
+		svc.l.Info("ranking pass complete", zap.String("biz", biz), zap.Int("count", len(items)))
+	}
+	return svc.cache.Set(ctx, biz, items)
+}
+
+// score 实现 Hacker-News 风格的打分公式：score = (like*w1 + collect*w2 + read*w3) / (ageHours + 2)^decay
+func (svc *rankingService) score(di domain.Interactive, now time.Time) float64 {
+	ageHours := now.Sub(time.UnixMilli(di.CreateTime)).Hours()
+	if ageHours < 0 {
+		ageHours = 0
+	}
+	w := svc.cfg.Weights
+	numerator := float64(di.LikeCount)*w.Like + float64(di.CollectCount)*w.Collect + float64(di.ReadCount)*w.Read
+	denominator := math.Pow(ageHours+2, svc.cfg.DecayExponent)
+	return numerator / denominator
+}
+
+func (svc *rankingService) GetTopN(ctx context.Context, biz string, n int) ([]domain.RankedItem, error) {
+	if n <= 0 {
+		n = svc.cfg.TopN
+	}
+	return svc.cache.GetTopN(ctx, biz, n)
+}
+
+// rankedItemHeap 是一个按 Score 升序排列的最小堆，堆顶始终是当前候选集中分数最低的条目，
+// 用于以 O(log TopN) 的代价维护“全局Top-N”而无需保存全部候选
+type rankedItemHeap []domain.RankedItem
+
+func (h rankedItemHeap) Len() int            { return len(h) }
+func (h rankedItemHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h rankedItemHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *rankedItemHeap) Push(x interface{}) { *h = append(*h, x.(domain.RankedItem)) }
+func (h *rankedItemHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// sorted 返回堆中元素按分数从高到低排列的切片，不修改原堆
+func (h rankedItemHeap) sorted() []domain.RankedItem {
+	cp := make(rankedItemHeap, len(h))
+	copy(cp, h)
+	result := make([]domain.RankedItem, 0, len(cp))
+	for cp.Len() > 0 {
+		result = append(result, heap.Pop(&cp).(domain.RankedItem))
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
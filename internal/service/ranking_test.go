@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/LinkMe/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCandidateRepo struct {
+	ids []int64
+}
+
+func (f *fakeCandidateRepo) ListCandidateIDs(_ context.Context, _ string, offset, limit int) ([]int64, error) {
+	if offset >= len(f.ids) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(f.ids) {
+		end = len(f.ids)
+	}
+	return f.ids[offset:end], nil
+}
+
+type fakeInteractiveService struct {
+	data map[int64]domain.Interactive
+}
+
+func (f *fakeInteractiveService) Like(context.Context, string, int64, int64) error         { return nil }
+func (f *fakeInteractiveService) CancelLike(context.Context, string, int64, int64) error   { return nil }
+func (f *fakeInteractiveService) Collect(context.Context, string, int64, int64, int64) error {
+	return nil
+}
+func (f *fakeInteractiveService) CancelCollect(context.Context, string, int64, int64, int64) error {
+	return nil
+}
+func (f *fakeInteractiveService) Get(context.Context, string, int64) (domain.Interactive, error) {
+	return domain.Interactive{}, nil
+}
+func (f *fakeInteractiveService) GetByIds(_ context.Context, _ string, ids []int64) (map[int64]domain.Interactive, error) {
+	out := make(map[int64]domain.Interactive, len(ids))
+	for _, id := range ids {
+		if di, ok := f.data[id]; ok {
+			out[id] = di
+		}
+	}
+	return out, nil
+}
+
+type fakeRankingCache struct {
+	lastSnapshot []domain.RankedItem
+}
+
+func (f *fakeRankingCache) Set(_ context.Context, _ string, items []domain.RankedItem) error {
+	f.lastSnapshot = items
+	return nil
+}
+func (f *fakeRankingCache) GetTopN(_ context.Context, _ string, n int) ([]domain.RankedItem, error) {
+	if n > len(f.lastSnapshot) {
+		n = len(f.lastSnapshot)
+	}
+	return f.lastSnapshot[:n], nil
+}
+
+func testConfig(topN int) RankingConfig {
+	return RankingConfig{
+		TopN:          topN,
+		BatchSize:     3,
+		Weights:       RankingWeights{Like: 1, Collect: 2, Read: 0.1},
+		DecayExponent: 1.5,
+		CacheTTL:      time.Minute,
+	}
+}
+
+func TestRankingService_Rank_KeepsOnlyTopN(t *testing.T) {
+	now := time.Now()
+	data := make(map[int64]domain.Interactive)
+	ids := make([]int64, 0, 20)
+	for i := int64(1); i <= 20; i++ {
+		ids = append(ids, i)
+		data[i] = domain.Interactive{
+			BizID:     i,
+			LikeCount: i, // 越大的ID点赞越多，分数应越高
+			CreateTime: now.UnixMilli(),
+		}
+	}
+
+	svc := NewRankingService(&fakeCandidateRepo{ids: ids}, &fakeInteractiveService{data: data}, &fakeRankingCache{}, testConfig(5), nil)
+	cacheImpl := svc.(*rankingService).cache.(*fakeRankingCache)
+
+	require.NoError(t, svc.Rank(context.Background(), "post"))
+	require.Len(t, cacheImpl.lastSnapshot, 5)
+
+	// Top5 应该是 id 16..20，按分数从高到低排列
+	for i, item := range cacheImpl.lastSnapshot {
+		assert.Equal(t, int64(20-i), item.BizID)
+	}
+	for i := 1; i < len(cacheImpl.lastSnapshot); i++ {
+		assert.GreaterOrEqual(t, cacheImpl.lastSnapshot[i-1].Score, cacheImpl.lastSnapshot[i].Score)
+	}
+}
+
+func TestRankingService_Score_DecaysMonotonicallyWithAge(t *testing.T) {
+	svc := &rankingService{cfg: testConfig(5)}
+	now := time.Now()
+	di := domain.Interactive{LikeCount: 100, CreateTime: now.Add(-1 * time.Hour).UnixMilli()}
+
+	scoreAt := func(elapsed time.Duration) float64 {
+		return svc.score(di, now.Add(elapsed))
+	}
+
+	s0 := scoreAt(0)
+	s1 := scoreAt(time.Hour)
+	s2 := scoreAt(2 * time.Hour)
+	assert.Greater(t, s0, s1)
+	assert.Greater(t, s1, s2)
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GoSimplicity/LinkMe/internal/domain"
+	"github.com/GoSimplicity/LinkMe/internal/domain/events/userstatus"
+	"github.com/GoSimplicity/LinkMe/internal/repository"
+	"github.com/GoSimplicity/LinkMe/internal/repository/cache"
+	"github.com/GoSimplicity/LinkMe/pkg/captcha"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrDuplicateEmail        = errors.New("duplicate email")
+	ErrInvalidUserOrPassword = errors.New("invalid email or password")
+	ErrCodeInvalid           = errors.New("verification code invalid or expired")
+	ErrCaptchaRequired       = errors.New("captcha required")
+	ErrCaptchaInvalid        = errors.New("captcha invalid")
+
+	// 账号状态相关错误，彼此独立以便上层返回区分度更高的响应码
+	ErrUserPendingEmailVerification = errors.New("user pending email verification")
+	ErrUserFrozen                   = errors.New("user frozen")
+	ErrUserBanned                   = errors.New("user banned")
+	ErrUserWrittenOff               = errors.New("user written off")
+	ErrIllegalStatusTransition      = errors.New("illegal user status transition")
+)
+
+// userStatusTransitions 定义合法的状态迁移：key为当前状态，value为允许迁移到的目标状态集合。
+// written_off 是终态，不出现在key中即代表不可再迁移。
+var userStatusTransitions = map[domain.UserStatus][]domain.UserStatus{
+	domain.UserStatusPendingEmailVerification: {domain.UserStatusActive},
+	domain.UserStatusActive:                   {domain.UserStatusFrozen, domain.UserStatusBanned, domain.UserStatusWrittenOff},
+	domain.UserStatusFrozen:                   {domain.UserStatusActive},
+	domain.UserStatusBanned:                   {domain.UserStatusActive},
+}
+
+func isLegalStatusTransition(from, to domain.UserStatus) bool {
+	for _, allowed := range userStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkActiveStatus 将非活跃状态映射为区分度更高的错误，由 Login/SignInSMS/SignInEmail 返回，使上层可返回不同的响应码
+func checkActiveStatus(status domain.UserStatus) error {
+	switch status {
+	case domain.UserStatusActive, "": // 空值兼容状态机上线前创建的历史数据
+		return nil
+	case domain.UserStatusPendingEmailVerification:
+		return ErrUserPendingEmailVerification
+	case domain.UserStatusFrozen:
+		return ErrUserFrozen
+	case domain.UserStatusBanned:
+		return ErrUserBanned
+	case domain.UserStatusWrittenOff:
+		return ErrUserWrittenOff
+	default:
+		return ErrUserFrozen
+	}
+}
+
+// GrantType 统一登录接口支持的授权方式，类比 LoginCommand.GrantType 的分发模式
+type GrantType string
+
+const (
+	GrantTypePassword  GrantType = "password"
+	GrantTypeSMSCode   GrantType = "sms_code"
+	GrantTypeEmailCode GrantType = "email_code"
+	GrantTypeCaptcha   GrantType = "captcha" // Auth 不分发该值，按不支持的授权方式处理
+)
+
+// captchaTriggerThreshold 同一邮箱/IP连续登录失败达到该次数后，密码登录必须携带验证码
+const captchaTriggerThreshold = 5
+
+// UserService 用户服务接口
+type UserService interface {
+	SignUp(ctx context.Context, u domain.User) error
+	Login(ctx context.Context, email string, password string) (domain.User, error)
+	ChangePassword(ctx context.Context, email, password, newPassword, confirmPassword string) error
+	DeleteUser(ctx context.Context, email string, password string, uid int64) error
+	GetProfileByUserID(ctx context.Context, uid int64) (domain.Profile, error)
+	UpdateProfile(ctx context.Context, profile domain.Profile) error
+	ListUser(ctx context.Context, pagination domain.Pagination) ([]domain.User, error)
+	GetUserCount(ctx context.Context) (int64, error)
+
+	// Auth 统一登录入口，根据 grantType 分发到具体的登录方式
+	Auth(ctx context.Context, grantType GrantType, identifier, credential, loginFailKey string) (domain.User, error)
+	// SignInPassword 邮箱+密码登录，达到失败阈值后要求携带验证码
+	SignInPassword(ctx context.Context, email, password, captchaID, captchaAnswer, loginFailKey string) (domain.User, error)
+	// SignInSMS 手机号+短信验证码登录
+	SignInSMS(ctx context.Context, phone, code string) (domain.User, error)
+	// SignInEmail 邮箱+邮箱验证码登录
+	SignInEmail(ctx context.Context, email, code string) (domain.User, error)
+
+	// GetStatus 返回用户当前的生命周期状态，供JWT中间件等场景做轻量校验
+	GetStatus(ctx context.Context, uid int64) (domain.UserStatus, error)
+	// TransitionStatus 按状态机校验并执行一次状态迁移，operatorID 为发起人（用户自身或管理员）
+	TransitionStatus(ctx context.Context, uid, operatorID int64, target domain.UserStatus, reason string) error
+	// FreezeUser 冻结用户
+	FreezeUser(ctx context.Context, uid, operatorID int64, reason string) error
+	// UnfreezeUser 解冻用户
+	UnfreezeUser(ctx context.Context, uid, operatorID int64, reason string) error
+	// BanUser 封禁用户
+	BanUser(ctx context.Context, uid, operatorID int64, reason string) error
+	// VerifyEmail 邮箱验证通过后将用户从待验证状态激活为active
+	VerifyEmail(ctx context.Context, uid int64) error
+}
+
+type userService struct {
+	repo           repository.UserRepository
+	codeCache      cache.CodeCache
+	loginFailCache cache.LoginFailCache
+	captcha        captcha.Captcha
+	statusProducer userstatus.Producer
+	l              *zap.Logger
+}
+
+func NewUserService(repo repository.UserRepository, codeCache cache.CodeCache, loginFailCache cache.LoginFailCache, cp captcha.Captcha, statusProducer userstatus.Producer, l *zap.Logger) UserService {
+	return &userService{
+		repo:           repo,
+		codeCache:      codeCache,
+		loginFailCache: loginFailCache,
+		captcha:        cp,
+		statusProducer: statusProducer,
+		l:              l,
+	}
+}
+
+func (svc *userService) SignUp(ctx context.Context, u domain.User) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.Password = string(hash)
+	return svc.repo.Create(ctx, u)
+}
+
+func (svc *userService) Login(ctx context.Context, email string, password string) (domain.User, error) {
+	u, err := svc.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return domain.User{}, ErrInvalidUserOrPassword
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+		return domain.User{}, ErrInvalidUserOrPassword
+	}
+	if err = checkActiveStatus(u.Status); err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+func (svc *userService) ChangePassword(ctx context.Context, email, password, newPassword, confirmPassword string) error {
+	if newPassword != confirmPassword {
+		return ErrInvalidUserOrPassword
+	}
+	u, err := svc.Login(ctx, email, password)
+	if err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	_ = u
+	return svc.repo.UpdatePassword(ctx, email, string(hash))
+}
+
+// DeleteUser 账号自助注销：校验身份后通过状态机迁移至 written_off 终态，而不是物理删除数据行，
+// 使注销账号纳入统一的生命周期管理（可被查询、审计，且不可再迁回 active）
+func (svc *userService) DeleteUser(ctx context.Context, email string, password string, uid int64) error {
+	if _, err := svc.Login(ctx, email, password); err != nil {
+		return err
+	}
+	return svc.TransitionStatus(ctx, uid, uid, domain.UserStatusWrittenOff, "self-service account write-off")
+}
+
+func (svc *userService) GetProfileByUserID(ctx context.Context, uid int64) (domain.Profile, error) {
+	u, err := svc.repo.FindByID(ctx, uid)
+	if err != nil {
+		return domain.Profile{}, err
+	}
+	return u.Profile, nil
+}
+
+func (svc *userService) UpdateProfile(ctx context.Context, profile domain.Profile) error {
+	return svc.repo.UpdateProfile(ctx, profile)
+}
+
+func (svc *userService) ListUser(ctx context.Context, pagination domain.Pagination) ([]domain.User, error) {
+	return svc.repo.List(ctx, pagination)
+}
+
+func (svc *userService) GetUserCount(ctx context.Context) (int64, error) {
+	return svc.repo.Count(ctx)
+}
+
+// Auth 按 grantType 分发到具体的登录方式，identifier/credential 的含义随 grantType 而变：
+// password: identifier=email, credential=password（不携带验证码，失败次数达到阈值后返回 ErrCaptchaRequired）
+// sms_code: identifier=phone, credential=code
+// email_code: identifier=email, credential=code
+// /api/users/auth 的 handler 不经过本方法，而是直接调用 SignInPassword/SignInSMS/SignInEmail
+func (svc *userService) Auth(ctx context.Context, grantType GrantType, identifier, credential, loginFailKey string) (domain.User, error) {
+	switch grantType {
+	case GrantTypePassword:
+		return svc.SignInPassword(ctx, identifier, credential, "", "", loginFailKey)
+	case GrantTypeSMSCode:
+		return svc.SignInSMS(ctx, identifier, credential)
+	case GrantTypeEmailCode:
+		return svc.SignInEmail(ctx, identifier, credential)
+	default:
+		return domain.User{}, fmt.Errorf("unsupported grant type: %s", grantType)
+	}
+}
+
+func (svc *userService) SignInPassword(ctx context.Context, email, password, captchaID, captchaAnswer, loginFailKey string) (domain.User, error) {
+	if loginFailKey != "" {
+		failCount, err := svc.loginFailCache.Peek(ctx, loginFailKey)
+		if err == nil && failCount >= captchaTriggerThreshold {
+			if captchaID == "" || captchaAnswer == "" {
+				return domain.User{}, ErrCaptchaRequired
+			}
+			ok, err := svc.captcha.Verify(ctx, captchaID, captchaAnswer)
+			if err != nil {
+				return domain.User{}, err
+			}
+			if !ok {
+				return domain.User{}, ErrCaptchaInvalid
+			}
+		}
+	}
+	u, err := svc.Login(ctx, email, password)
+	if err != nil {
+		// 账号状态类错误不代表凭证错误，不计入失败次数，否则被封禁用户反复登录会误触发验证码门槛
+		if loginFailKey != "" && errors.Is(err, ErrInvalidUserOrPassword) {
+			if _, incrErr := svc.loginFailCache.Incr(ctx, loginFailKey); incrErr != nil {
+				svc.l.Error("incr login fail count failed", zap.Error(incrErr))
+			}
+		}
+		return domain.User{}, err
+	}
+	if loginFailKey != "" {
+		if resetErr := svc.loginFailCache.Reset(ctx, loginFailKey); resetErr != nil {
+			svc.l.Error("reset login fail count failed", zap.Error(resetErr))
+		}
+	}
+	return u, nil
+}
+
+func (svc *userService) SignInSMS(ctx context.Context, phone, code string) (domain.User, error) {
+	ok, err := svc.codeCache.Verify(ctx, "login", phone, code)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !ok {
+		return domain.User{}, ErrCodeInvalid
+	}
+	u, err := svc.repo.FindByPhone(ctx, phone)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err = checkActiveStatus(u.Status); err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+func (svc *userService) SignInEmail(ctx context.Context, email, code string) (domain.User, error) {
+	ok, err := svc.codeCache.Verify(ctx, "login", email, code)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !ok {
+		return domain.User{}, ErrCodeInvalid
+	}
+	u, err := svc.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err = checkActiveStatus(u.Status); err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+func (svc *userService) GetStatus(ctx context.Context, uid int64) (domain.UserStatus, error) {
+	u, err := svc.repo.FindByID(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	return u.Status, nil
+}
+
+// TransitionStatus 校验状态机后执行迁移，并在成功后同步发送状态变更事件通知下游系统（搜索索引、帖子可见性等）；
+// 事件发送失败只记录日志，不影响迁移结果
+func (svc *userService) TransitionStatus(ctx context.Context, uid, operatorID int64, target domain.UserStatus, reason string) error {
+	u, err := svc.repo.FindByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	from := u.Status
+	if from == "" {
+		from = domain.UserStatusActive
+	}
+	if !isLegalStatusTransition(from, target) {
+		return ErrIllegalStatusTransition
+	}
+	if err = svc.repo.UpdateStatus(ctx, uid, target, reason); err != nil {
+		return err
+	}
+	if svc.statusProducer != nil {
+		if err = svc.statusProducer.ProduceStatusChanged(ctx, userstatus.UserStatusChangedEvent{
+			UID:        uid,
+			FromStatus: string(from),
+			ToStatus:   string(target),
+			Reason:     reason,
+			OperatorID: operatorID,
+		}); err != nil {
+			svc.l.Error("produce user status changed event failed", zap.Int64("uid", uid), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (svc *userService) FreezeUser(ctx context.Context, uid, operatorID int64, reason string) error {
+	return svc.TransitionStatus(ctx, uid, operatorID, domain.UserStatusFrozen, reason)
+}
+
+func (svc *userService) UnfreezeUser(ctx context.Context, uid, operatorID int64, reason string) error {
+	u, err := svc.repo.FindByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Status != domain.UserStatusFrozen {
+		return ErrIllegalStatusTransition
+	}
+	return svc.TransitionStatus(ctx, uid, operatorID, domain.UserStatusActive, reason)
+}
+
+func (svc *userService) BanUser(ctx context.Context, uid, operatorID int64, reason string) error {
+	return svc.TransitionStatus(ctx, uid, operatorID, domain.UserStatusBanned, reason)
+}
+
+func (svc *userService) VerifyEmail(ctx context.Context, uid int64) error {
+	u, err := svc.repo.FindByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Status != domain.UserStatusPendingEmailVerification {
+		return ErrIllegalStatusTransition
+	}
+	return svc.TransitionStatus(ctx, uid, uid, domain.UserStatusActive, "email verified")
+}
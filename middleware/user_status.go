@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+
+	. "github.com/GoSimplicity/LinkMe/internal/constants"
+	"github.com/GoSimplicity/LinkMe/internal/domain"
+	"github.com/GoSimplicity/LinkMe/internal/service"
+	ijwt "github.com/GoSimplicity/LinkMe/utils/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// UserStatusMiddleware 在JWT校验之后进一步校验账号生命周期状态，非active账号一律拒绝，
+// 并按状态返回不同的响应码，便于前端展示准确的提示文案
+type UserStatusMiddleware struct {
+	svc service.UserService
+}
+
+func NewUserStatusMiddleware(svc service.UserService) *UserStatusMiddleware {
+	return &UserStatusMiddleware{svc: svc}
+}
+
+func (m *UserStatusMiddleware) CheckActive() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		val, ok := ctx.Get("user")
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		claims, ok := val.(ijwt.UserClaims)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		status, err := m.svc.GetStatus(ctx.Request.Context(), claims.Uid)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if code, msg, blocked := StatusRejection(status); blocked {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": code, "msg": msg})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// StatusRejection 将账号状态映射为响应码/提示信息，blocked=false 表示该状态允许放行。
+// 导出供 api 包的同构判断（如 RefreshToken）复用，避免状态->响应码的映射重复维护
+func StatusRejection(status domain.UserStatus) (code int, msg string, blocked bool) {
+	switch status {
+	case domain.UserStatusActive, "":
+		return 0, "", false
+	case domain.UserStatusPendingEmailVerification:
+		return UserPendingEmailVerificationCode, UserCodeInvalid, true
+	case domain.UserStatusFrozen:
+		return UserFrozenCode, UserLoginFailure, true
+	case domain.UserStatusBanned:
+		return UserBannedCode, UserLoginFailure, true
+	case domain.UserStatusWrittenOff:
+		return UserWrittenOffCode, UserLoginFailure, true
+	default:
+		return UserFrozenCode, UserLoginFailure, true
+	}
+}
@@ -0,0 +1,19 @@
+package captcha
+
+import "context"
+
+// Kind 验证码类型
+type Kind string
+
+const (
+	KindImage  Kind = "image"  // 图形验证码
+	KindSlider Kind = "slider" // 滑块验证码
+)
+
+// Captcha 人机验证接口，用于在高风险操作前拦截自动化请求
+type Captcha interface {
+	// Generate 生成一个验证码，返回验证码ID与展示给前端的内容（如base64图片）
+	Generate(ctx context.Context, kind Kind) (id string, payload string, err error)
+	// Verify 校验验证码答案，无论成败验证码都应被消费，不可重复使用
+	Verify(ctx context.Context, id string, answer string) (bool, error)
+}
@@ -0,0 +1,42 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+
+	b64captcha "github.com/mojocn/base64Captcha"
+	"github.com/redis/go-redis/v9"
+)
+
+// imageCaptcha 生成数字图形验证码，答案即图中展示的数字串
+type imageCaptcha struct {
+	store  *store
+	driver b64captcha.Driver
+}
+
+// NewImageCaptcha 创建图形验证码实现
+func NewImageCaptcha(client redis.Cmdable) Captcha {
+	return &imageCaptcha{
+		store:  newStore(client),
+		driver: b64captcha.NewDriverDigit(80, 240, 5, 0.7, 80),
+	}
+}
+
+func (c *imageCaptcha) Generate(ctx context.Context, kind Kind) (string, string, error) {
+	if kind != KindImage {
+		return "", "", fmt.Errorf("imageCaptcha does not support kind %q", kind)
+	}
+	id, content, answer := c.driver.GenerateIdQuestionAnswer()
+	item, err := c.driver.DrawCaptcha(content)
+	if err != nil {
+		return "", "", err
+	}
+	if err = c.store.save(ctx, id, answer); err != nil {
+		return "", "", err
+	}
+	return id, item.EncodeB64string(), nil
+}
+
+func (c *imageCaptcha) Verify(ctx context.Context, id string, answer string) (bool, error) {
+	return c.store.verify(ctx, id, answer)
+}
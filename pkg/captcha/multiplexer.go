@@ -0,0 +1,39 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Multiplexer 按 kind 路由到具体实现，向上层暴露单一的 Captcha 接口，新增验证码类型时无需改动调用方
+type Multiplexer struct {
+	impls map[Kind]Captcha
+	store *store
+}
+
+// NewMultiplexer 组装一个同时支持图形与滑块验证码的 Captcha。image/slider 的校验格式与存储（同一个
+// Redis key 命名空间）无关，统一持有一个 store 直接校验，而不是任选一个 impl 代为转发
+func NewMultiplexer(client redis.Cmdable, image, slider Captcha) *Multiplexer {
+	return &Multiplexer{
+		impls: map[Kind]Captcha{
+			KindImage:  image,
+			KindSlider: slider,
+		},
+		store: newStore(client),
+	}
+}
+
+func (m *Multiplexer) Generate(ctx context.Context, kind Kind) (string, string, error) {
+	impl, ok := m.impls[kind]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported captcha kind: %s", kind)
+	}
+	return impl.Generate(ctx, kind)
+}
+
+// Verify 验证码的存储格式与生成它的具体实现无关，直接对共享 store 校验即可，无需也不应依赖任意选中的 impl
+func (m *Multiplexer) Verify(ctx context.Context, id string, answer string) (bool, error) {
+	return m.store.verify(ctx, id, answer)
+}
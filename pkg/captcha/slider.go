@@ -0,0 +1,40 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// sliderCaptcha 用一道简单算术题代替真正的拖拽滑块，交互成本更低但同样能拦截无脑重放脚本
+type sliderCaptcha struct {
+	store *store
+}
+
+// NewSliderCaptcha 创建滑块（算术题）验证码实现
+func NewSliderCaptcha(client redis.Cmdable) Captcha {
+	return &sliderCaptcha{store: newStore(client)}
+}
+
+func (c *sliderCaptcha) Generate(ctx context.Context, kind Kind) (string, string, error) {
+	if kind != KindSlider {
+		return "", "", fmt.Errorf("sliderCaptcha does not support kind %q", kind)
+	}
+	a := rand.Intn(10) + 1
+	b := rand.Intn(10) + 1
+	id := uuid.NewString()
+	answer := strconv.Itoa(a + b)
+	if err := c.store.save(ctx, id, answer); err != nil {
+		return "", "", err
+	}
+	payload := fmt.Sprintf("%d + %d = ?", a, b)
+	return id, payload, nil
+}
+
+func (c *sliderCaptcha) Verify(ctx context.Context, id string, answer string) (bool, error) {
+	return c.store.verify(ctx, id, answer)
+}
@@ -0,0 +1,49 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ttl 验证码的有效期，超时未校验自动失效
+const ttl = 2 * time.Minute
+
+// store 负责验证码答案的存取，Redis 是唯一真相源，保证多实例部署下验证码可被任意实例校验
+type store struct {
+	client redis.Cmdable
+}
+
+func newStore(client redis.Cmdable) *store {
+	return &store{client: client}
+}
+
+func (s *store) key(id string) string {
+	return fmt.Sprintf("captcha:%s", id)
+}
+
+func (s *store) save(ctx context.Context, id, answer string) error {
+	return s.client.Set(ctx, s.key(id), answer, ttl).Err()
+}
+
+// verify 答案只要被校验过一次就立即失效（无论对错），防止对同一验证码反复枚举
+func (s *store) verify(ctx context.Context, id, answer string) (bool, error) {
+	if id == "" {
+		return false, nil
+	}
+	key := s.key(id)
+	expected, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if delErr := s.client.Del(ctx, key).Err(); delErr != nil {
+		return false, delErr
+	}
+	return expected == answer, nil
+}